@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+)
+
+// Result is a single page fetched by CrawlStream.
+type Result struct {
+	URL   string
+	Body  string
+	Err   error
+	Depth int
+}
+
+// CrawlStream crawls like Crawl, but streams each fetched page back on the
+// returned channel instead of only populating the cache. The channel is
+// closed exactly once, after the entire recursive crawl has completed, so
+// callers can safely range over it:
+//
+//	for r := range CrawlStream(ctx, url, depth, fetcher, CrawlOptions{}) {
+//		...
+//	}
+//
+// If ctx is canceled, in-flight fetches are allowed to finish but no new
+// results are delivered and no further pages are crawled; the channel is
+// still closed once the pool has drained.
+func CrawlStream(ctx context.Context, url string, depth int, fetcher Fetcher, opts CrawlOptions) <-chan Result {
+	ch := make(chan Result)
+	go func() {
+		defer close(ch)
+		runPool(ctx, url, depth, fetcher, opts, newCrawlerCache(), ch)
+	}()
+	return ch
+}
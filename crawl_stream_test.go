@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// drain reads every Result off ch and returns the set of URLs seen.
+func drain(ch <-chan Result) map[string]bool {
+	seen := make(map[string]bool)
+	for r := range ch {
+		seen[r.URL] = true
+	}
+	return seen
+}
+
+func wantURLs() map[string]bool {
+	return map[string]bool{
+		"http://golang.org/":         true,
+		"http://golang.org/pkg/":     true,
+		"http://golang.org/cmd/":     true,
+		"http://golang.org/pkg/fmt/": true,
+		"http://golang.org/pkg/os/":  true,
+	}
+}
+
+// TestCrawlStream_RepeatedSeed guards against the dedupe cache being a
+// process-wide singleton: crawling the same seed twice in a row must
+// produce the full result set both times, not just the first.
+func TestCrawlStream_RepeatedSeed(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		got := drain(CrawlStream(context.Background(), "http://golang.org/", 4, fetcher, CrawlOptions{}))
+		if len(got) != len(wantURLs()) {
+			t.Fatalf("run %d: got %d results, want %d: %v", i, len(got), len(wantURLs()), got)
+		}
+		for url := range wantURLs() {
+			if !got[url] {
+				t.Errorf("run %d: missing %s", i, url)
+			}
+		}
+	}
+}
+
+// TestCrawlStream_ConcurrentSeeds guards against the same singleton-cache
+// problem surfacing across two crawls running at the same time instead of
+// back to back.
+func TestCrawlStream_ConcurrentSeeds(t *testing.T) {
+	const n = 4
+
+	var wg sync.WaitGroup
+	results := make([]map[string]bool, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = drain(CrawlStream(context.Background(), "http://golang.org/", 4, fetcher, CrawlOptions{}))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, got := range results {
+		if len(got) != len(wantURLs()) {
+			t.Errorf("crawl %d: got %d results, want %d: %v", i, len(got), len(wantURLs()), got)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+type Fetcher interface {
+	// Fetch returns the body of URL and a slice of URLs found on that page.
+	// Implementations should abort and return once ctx is done, so a caller
+	// that stops reading a CrawlStream can actually tear down in-flight
+	// fetches instead of leaking a blocked worker.
+	Fetch(ctx context.Context, url string) (body string, urls []string, err error)
+}
+
+// A place to cache the results of crawling pages
+type crawlerCache struct {
+	m map[string]fetchResult
+	sync.Mutex
+}
+
+// claim atomically checks whether rawURL has already been seen and, if not,
+// marks it as loading. It returns the normalized cache key alongside true
+// iff the caller won the race and is now responsible for fetching it; the
+// returned key is what the caller must use for the later call to Add, so
+// that trivially different URLs (different casing, query param order, a
+// trailing "/./") still land on the same cache entry.
+func (c *crawlerCache) claim(rawURL string) (key string, claimed bool) {
+	key = normalizedCacheKey(rawURL)
+	c.Lock()
+	defer c.Unlock()
+	if _, found := c.m[key]; found {
+		return key, false
+	}
+	c.m[key] = loading
+	return key, true
+}
+
+// Add a key, making sure nobody else is modifying the cache at the same time
+func (c *crawlerCache) Add(key string, val fetchResult) {
+	c.Lock()
+	c.m[key] = val
+	c.Unlock()
+}
+
+// Remove releases a claim on key without recording a result, so a later
+// crawl can retry it. Used when a claimed URL never actually gets fetched
+// for reasons that have nothing to do with the URL itself (e.g. the caller's
+// context was canceled).
+func (c *crawlerCache) Remove(key string) {
+	c.Lock()
+	delete(c.m, key)
+	c.Unlock()
+}
+
+// newCrawlerCache returns an empty cache, scoped to a single Crawl or
+// CrawlStream call. Each call gets its own cache rather than sharing one
+// process-wide, so that crawling the same seed twice (or two overlapping
+// seeds concurrently) doesn't have the second crawl see every URL as
+// already claimed by the first.
+func newCrawlerCache() *crawlerCache {
+	return &crawlerCache{m: make(map[string]fetchResult)}
+}
+
+type fetchResult struct {
+	body string
+	urls []string
+	err  error
+}
+
+var loading = fetchResult{} // dummy value to put in cache while crawling is in progress
+
+// Crawl uses fetcher to recursively crawl pages starting with url, to a
+// maximum of depth, fetching at most opts.Workers pages concurrently. It
+// blocks until the whole crawl completes and returns every page reached,
+// keyed by its normalized URL:
+//
+//	pages := Crawl(url, depth, fetcher, CrawlOptions{})
+func Crawl(url string, depth int, fetcher Fetcher, opts CrawlOptions) map[string]fetchResult {
+	c := newCrawlerCache()
+	runPool(context.Background(), url, depth, fetcher, opts, c, nil)
+	return c.m
+}
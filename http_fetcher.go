@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTTPFetcher is a Fetcher that fetches real pages over HTTP(S) and parses
+// the anchors out of the returned HTML with golang.org/x/net/html. It plugs
+// into Crawl exactly like fakeFetcher does.
+type HTTPFetcher struct {
+	// Client is used to make requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// AllowedHosts restricts which hosts links may be followed to. If empty,
+	// HTTPFetcher only follows links back to the host of the page it found
+	// them on (same-origin).
+	AllowedHosts []string
+}
+
+// NewHTTPFetcher returns an HTTPFetcher restricted to the given allowed
+// hosts. If no hosts are given, the fetcher falls back to same-origin links
+// only.
+func NewHTTPFetcher(allowedHosts ...string) *HTTPFetcher {
+	return &HTTPFetcher{AllowedHosts: allowedHosts}
+}
+
+// Fetch implements Fetcher by issuing a GET request for url and extracting
+// the hrefs of every <a> tag in the response body. It aborts as soon as ctx
+// is done, so a worker blocked on a slow or unresponsive server can actually
+// be torn down instead of wedging forever.
+func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (body string, urls []string, err error) {
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing %s: %w", rawURL, err)
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("building request for %s: %w", rawURL, err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("fetching %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("fetching %s: status %s", rawURL, resp.Status)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading body of %s: %w", rawURL, err)
+	}
+	body = string(bodyBytes)
+
+	links, err := extractLinks(base, strings.NewReader(body))
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing body of %s: %w", rawURL, err)
+	}
+
+	for _, link := range links {
+		if f.allowed(base, link) {
+			urls = append(urls, link.String())
+		}
+	}
+
+	return body, urls, nil
+}
+
+// extractLinks walks the HTML document in r and returns every href found on
+// an <a> tag, resolved against base.
+func extractLinks(base *url.URL, r io.Reader) ([]*url.URL, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []*url.URL
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			for _, attr := range n.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				ref, err := url.Parse(attr.Val)
+				if err != nil {
+					continue
+				}
+				links = append(links, base.ResolveReference(ref))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return links, nil
+}
+
+// allowed reports whether link may be followed, given it was found on base.
+func (f *HTTPFetcher) allowed(base, link *url.URL) bool {
+	if link.Scheme != "http" && link.Scheme != "https" {
+		return false
+	}
+
+	if len(f.AllowedHosts) == 0 {
+		return link.Hostname() == base.Hostname()
+	}
+
+	for _, host := range f.AllowedHosts {
+		if link.Hostname() == host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package main
+
+import "sync"
+
+// jobQueue is an unbounded FIFO queue of crawlJobs, shared by the pool's
+// workers. Unlike a buffered channel, push never blocks the caller, so
+// discovering many child URLs at once doesn't require spawning a goroutine
+// per URL just to get them queued.
+type jobQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []crawlJob
+	closed bool
+}
+
+func newJobQueue() *jobQueue {
+	q := &jobQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds j to the queue and wakes a worker blocked in pop.
+func (q *jobQueue) push(j crawlJob) {
+	q.mu.Lock()
+	q.items = append(q.items, j)
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// close marks the queue as drained: no more jobs will be pushed. Workers
+// blocked in pop wake up and, once the remaining items are consumed, get
+// ok == false.
+func (q *jobQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// pop blocks until a job is available, or the queue is closed and empty.
+func (q *jobQueue) pop() (j crawlJob, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return crawlJob{}, false
+	}
+	j, q.items = q.items[0], q.items[1:]
+	return j, true
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
+)
+
+// errPolicyDisallowed is recorded in the cache in place of a fetch error
+// when a Policy vetoes a URL, so it shows up like any other failed fetch
+// instead of being silently dropped.
+var errPolicyDisallowed = errors.New("policy disallowed fetch")
+
+// Policy is consulted before every fetcher.Fetch call made by a crawl. It
+// can block a URL outright (robots.txt) or simply make the caller wait
+// (rate limiting); either way Allow should only return once rawURL is safe
+// to fetch, or ctx is done.
+type Policy interface {
+	Allow(ctx context.Context, rawURL string) bool
+}
+
+// DefaultPolicy is a Policy that rate limits requests per host with a
+// golang.org/x/time/rate token bucket and honors each host's robots.txt.
+// The zero value is ready to use.
+type DefaultPolicy struct {
+	// Client is used to fetch robots.txt. If nil, http.DefaultClient is
+	// used.
+	Client *http.Client
+
+	// UserAgent is sent on robots.txt requests and is the name DefaultPolicy
+	// looks for its own rules under (falling back to the "*" group). If
+	// empty, "Go-http-client" is used for both.
+	UserAgent string
+
+	// RatePerSecond is the per-host request rate allowed. If zero,
+	// DefaultRatePerSecond is used.
+	RatePerSecond rate.Limit
+	// Burst is the per-host burst size allowed. If zero, DefaultBurst is
+	// used.
+	Burst int
+
+	mu          sync.Mutex
+	limiters    map[string]*rate.Limiter
+	robots      map[string]*robotsRules
+	robotsFetch singleflight.Group // collapses concurrent first-fetches of the same host's robots.txt
+}
+
+// DefaultRatePerSecond and DefaultBurst are the rate limit DefaultPolicy
+// applies to a host when RatePerSecond/Burst are left unset.
+const (
+	DefaultRatePerSecond rate.Limit = 1
+	DefaultBurst         int        = 1
+)
+
+// Allow blocks until rawURL's host has rate-limit capacity available and
+// robots.txt permits it, or ctx is done.
+func (p *DefaultPolicy) Allow(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	if !p.robotsAllow(u) {
+		return false
+	}
+
+	if err := p.limiterFor(u.Hostname()).Wait(ctx); err != nil {
+		return false
+	}
+	return true
+}
+
+func (p *DefaultPolicy) userAgent() string {
+	if p.UserAgent != "" {
+		return p.UserAgent
+	}
+	return "Go-http-client"
+}
+
+func (p *DefaultPolicy) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return http.DefaultClient
+}
+
+func (p *DefaultPolicy) limiterFor(host string) *rate.Limiter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.limiters == nil {
+		p.limiters = make(map[string]*rate.Limiter)
+	}
+	l, ok := p.limiters[host]
+	if !ok {
+		rps := p.RatePerSecond
+		if rps <= 0 {
+			rps = DefaultRatePerSecond
+		}
+		burst := p.Burst
+		if burst <= 0 {
+			burst = DefaultBurst
+		}
+		l = rate.NewLimiter(rps, burst)
+		p.limiters[host] = l
+	}
+	return l
+}
+
+// robotsRules is the subset of a robots.txt we act on: the Disallow path
+// prefixes that apply to us.
+type robotsRules struct {
+	disallow []string
+}
+
+func (rules *robotsRules) allows(path string) bool {
+	for _, prefix := range rules.disallow {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *DefaultPolicy) robotsAllow(u *url.URL) bool {
+	host := u.Hostname()
+
+	p.mu.Lock()
+	rules, cached := p.robots[host]
+	p.mu.Unlock()
+	if !cached {
+		// The pool dispatches many same-host URLs to different workers up
+		// front, so several can race to fetch this host's robots.txt for the
+		// first time; singleflight collapses them into one request.
+		v, _, _ := p.robotsFetch.Do(host, func() (interface{}, error) {
+			return p.fetchRobots(u), nil
+		})
+		rules = v.(*robotsRules)
+
+		p.mu.Lock()
+		if p.robots == nil {
+			p.robots = make(map[string]*robotsRules)
+		}
+		p.robots[host] = rules
+		p.mu.Unlock()
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	return rules.allows(path)
+}
+
+// fetchRobots fetches and parses the robots.txt for u's host. Any failure to
+// fetch or parse it is treated as "no rules", matching how real crawlers
+// behave when robots.txt is missing.
+func (p *DefaultPolicy) fetchRobots(u *url.URL) *robotsRules {
+	robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequest(http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return &robotsRules{}
+	}
+	req.Header.Set("User-Agent", p.userAgent())
+
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return &robotsRules{}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}
+	}
+
+	return parseRobots(resp.Body, p.userAgent())
+}
+
+// robotsGroup is one "User-agent: ... \n Disallow: ..." block. robots.txt
+// allows several consecutive User-agent lines to share the rules that
+// follow, so a group can list more than one agent.
+type robotsGroup struct {
+	agents   []string
+	disallow []string
+}
+
+// parseRobots is a minimal robots.txt parser: it groups consecutive
+// User-agent lines together, then honors the Disallow rules of whichever
+// group names us specifically, falling back to the "*" group. It doesn't
+// implement Allow overrides, wildcards, or Crawl-delay; good enough to steer
+// well clear of paths a site has explicitly opted out of.
+func parseRobots(r io.Reader, userAgent string) *robotsRules {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	startNewGroup := true // true once a non-user-agent directive has broken the run of user-agent lines
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+
+		switch key {
+		case "user-agent":
+			if startNewGroup {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				startNewGroup = false
+			}
+			current.agents = append(current.agents, strings.ToLower(val))
+		case "disallow":
+			startNewGroup = true
+			if current != nil && val != "" {
+				current.disallow = append(current.disallow, val)
+			}
+		case "allow":
+			startNewGroup = true
+		}
+	}
+
+	// An exact match for our own user-agent wins over the "*" group, same
+	// as every real robots.txt implementation.
+	var specific, wildcard *robotsGroup
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			switch {
+			case strings.EqualFold(agent, userAgent):
+				specific = g
+			case agent == "*" && wildcard == nil:
+				wildcard = g
+			}
+		}
+	}
+
+	switch {
+	case specific != nil:
+		return &robotsRules{disallow: specific.disallow}
+	case wildcard != nil:
+		return &robotsRules{disallow: wildcard.disallow}
+	default:
+		return &robotsRules{}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name      string
+		robotsTxt string
+		userAgent string
+		disallow  []string
+		allow     []string
+	}{
+		{
+			name: "wildcard group applies when no specific group exists",
+			robotsTxt: `
+				User-agent: *
+				Disallow: /private
+			`,
+			userAgent: "my-bot",
+			disallow:  []string{"/private"},
+			allow:     []string{"/public"},
+		},
+		{
+			name: "specific group wins over wildcard",
+			robotsTxt: `
+				User-agent: *
+				Disallow: /everything
+
+				User-agent: my-bot
+				Disallow: /only-for-me
+			`,
+			userAgent: "my-bot",
+			disallow:  []string{"/only-for-me"},
+			allow:     []string{"/everything"},
+		},
+		{
+			name: "agents sharing a group share its rules",
+			robotsTxt: `
+				User-agent: my-bot
+				User-agent: *
+				Disallow: /shared
+			`,
+			userAgent: "my-bot",
+			disallow:  []string{"/shared"},
+		},
+		{
+			name: "comments are stripped",
+			robotsTxt: `
+				# full line comment
+				User-agent: * # inline comment
+				Disallow: /private # also disallowed
+			`,
+			userAgent: "my-bot",
+			disallow:  []string{"/private"},
+		},
+		{
+			name: "empty disallow means allow everything",
+			robotsTxt: `
+				User-agent: *
+				Disallow:
+			`,
+			userAgent: "my-bot",
+			allow:     []string{"/anything"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(tt.robotsTxt), tt.userAgent)
+			for _, path := range tt.disallow {
+				if rules.allows(path) {
+					t.Errorf("expected %s to be disallowed, but it was allowed", path)
+				}
+			}
+			for _, path := range tt.allow {
+				if !rules.allows(path) {
+					t.Errorf("expected %s to be allowed, but it was disallowed", path)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// CrawlOptions controls how a crawl is executed.
+type CrawlOptions struct {
+	// Workers is the number of goroutines that may have a fetcher.Fetch
+	// call in flight at once. If zero or negative, DefaultWorkers is used.
+	Workers int
+
+	// Policy, if set, is consulted before every fetcher.Fetch call and can
+	// veto it (rate limiting, robots.txt, ...). If nil, every fetch is
+	// allowed.
+	Policy Policy
+}
+
+// DefaultWorkers is the worker pool size used when CrawlOptions.Workers is
+// not set.
+const DefaultWorkers = 10
+
+func (o CrawlOptions) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+	return DefaultWorkers
+}
+
+// crawlJob is a unit of work on the pool's frontier: a URL discovered at a
+// given remaining depth.
+type crawlJob struct {
+	url   string
+	depth int
+}
+
+// runPool crawls starting at seed using a fixed-size pool of opts.workers()
+// goroutines, blocking until the frontier has fully drained. Discovered
+// pages are recorded in c. If results is non-nil, each fetched page is sent
+// to it as it completes; runPool never closes results, so callers that pass
+// a channel are responsible for closing it once runPool returns.
+//
+// Outstanding work is tracked with a sync.WaitGroup: one unit per queued job,
+// marked done once that job (and the enqueuing of its children) completes.
+// A separate goroutine closes the job queue once the WaitGroup reaches zero,
+// which is what lets the workers below drain and exit. The queue itself
+// never blocks a push, so a page with many links doesn't spawn a goroutine
+// per link just to get them queued; opts.workers() still bounds how many
+// fetches are in flight at once.
+func runPool(ctx context.Context, seed string, depth int, fetcher Fetcher, opts CrawlOptions, c *crawlerCache, results chan<- Result) {
+	queue := newJobQueue()
+	var frontier sync.WaitGroup
+
+	enqueue := func(j crawlJob) {
+		frontier.Add(1)
+		queue.push(j)
+	}
+	enqueue(crawlJob{seed, depth})
+
+	go func() {
+		frontier.Wait()
+		queue.close()
+	}()
+
+	var workers sync.WaitGroup
+	workers.Add(opts.workers())
+	for i := 0; i < opts.workers(); i++ {
+		go func() {
+			defer workers.Done()
+			for {
+				job, ok := queue.pop()
+				if !ok {
+					return
+				}
+				processJob(ctx, job, fetcher, opts, c, results, enqueue)
+				frontier.Done()
+			}
+		}()
+	}
+	workers.Wait()
+}
+
+// processJob fetches a single job's URL, records it in c, optionally
+// streams it to results, and enqueues its children for the remaining depth.
+func processJob(ctx context.Context, job crawlJob, fetcher Fetcher, opts CrawlOptions, c *crawlerCache, results chan<- Result, enqueue func(crawlJob)) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	// have we already hit the max depth, or already seen this url?
+	if job.depth <= 0 {
+		return
+	}
+	key, claimed := c.claim(job.url)
+	if !claimed {
+		return
+	}
+
+	if opts.Policy != nil && !opts.Policy.Allow(ctx, job.url) {
+		if ctx.Err() != nil {
+			// Allow only said no because ctx gave up, not because the policy
+			// actually vetoed this URL; release the claim so a future crawl
+			// with a live context can still try it.
+			c.Remove(key)
+			return
+		}
+		c.Add(key, fetchResult{err: errPolicyDisallowed})
+		if results != nil {
+			select {
+			case results <- Result{URL: job.url, Err: errPolicyDisallowed, Depth: job.depth}:
+			case <-ctx.Done():
+			}
+		}
+		return
+	}
+
+	body, urls, err := fetcher.Fetch(ctx, job.url)
+	c.Add(key, fetchResult{body, urls, err})
+
+	if results != nil {
+		select {
+		case results <- Result{URL: job.url, Body: body, Err: err, Depth: job.depth}:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	if err != nil {
+		return
+	}
+
+	for _, childURL := range urls {
+		enqueue(crawlJob{childURL, job.depth - 1})
+	}
+}
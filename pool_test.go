@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCrawl checks Crawl's basic dedupe-and-depth behavior using the
+// package's canned fetcher: every reachable page shows up exactly once, and
+// depth 1 stops after the seed.
+func TestCrawl(t *testing.T) {
+	pages := Crawl("http://golang.org/", 4, fetcher, CrawlOptions{})
+	for url := range wantURLs() {
+		if _, ok := pages[url]; !ok {
+			t.Errorf("missing %s", url)
+		}
+	}
+	if res := pages["http://golang.org/"]; res.body != "The Go Programming Language" {
+		t.Errorf("seed page: got body %q", res.body)
+	}
+
+	shallow := Crawl("http://golang.org/", 1, fetcher, CrawlOptions{})
+	if len(shallow) != 1 {
+		t.Errorf("depth 1: got %d pages, want 1: %v", len(shallow), shallow)
+	}
+	if _, ok := shallow["http://golang.org/"]; !ok {
+		t.Errorf("depth 1: missing seed page")
+	}
+}
+
+// boundedFetcher tracks how many Fetch calls are concurrently in flight and
+// fails the test if that ever exceeds the configured limit.
+type boundedFetcher struct {
+	t        *testing.T
+	limit    int64
+	inFlight int64
+}
+
+func (f *boundedFetcher) Fetch(ctx context.Context, url string) (string, []string, error) {
+	n := atomic.AddInt64(&f.inFlight, 1)
+	defer atomic.AddInt64(&f.inFlight, -1)
+	if n > f.limit {
+		f.t.Errorf("%d fetches in flight at once, want at most %d", n, f.limit)
+	}
+	// Give other workers a chance to pile on, so the bound is actually
+	// exercised instead of each fetch finishing before the next starts.
+	time.Sleep(10 * time.Millisecond)
+	return "", []string{url + "/a", url + "/b", url + "/c"}, nil
+}
+
+// TestCrawl_BoundsConcurrency checks that the worker pool never has more
+// than opts.Workers fetches in flight at once, even when a page has many
+// more children than that.
+func TestCrawl_BoundsConcurrency(t *testing.T) {
+	const workers = 3
+	f := &boundedFetcher{t: t, limit: workers}
+	Crawl("http://example.com/", 3, f, CrawlOptions{Workers: workers})
+}
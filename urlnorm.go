@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// normalizedCacheKey returns the cache key used to dedupe rawURL: the host
+// lowercased, the path cleaned of "." and ".." segments, query params sorted
+// by key, and any fragment stripped. This keeps trivially different URLs
+// (different host casing, reordered query params, a "#section" fragment)
+// from being fetched more than once. If rawURL doesn't parse, it's used
+// as-is so the crawl can still report the failure instead of panicking.
+func normalizedCacheKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+
+	if u.Path == "" {
+		// "http://example.com" and "http://example.com/" are the same
+		// resource.
+		u.Path = "/"
+	} else {
+		hadTrailingSlash := strings.HasSuffix(u.Path, "/")
+		cleaned := path.Clean(u.Path)
+		if cleaned == "." {
+			cleaned = "/"
+		}
+		if hadTrailingSlash && cleaned != "/" && !strings.HasSuffix(cleaned, "/") {
+			// path.Clean drops a trailing slash, but "/blog" and "/blog/" can be
+			// different resources, so don't let them collide in the cache.
+			cleaned += "/"
+		}
+		u.Path = cleaned
+	}
+
+	if u.RawQuery != "" {
+		// Values.Encode sorts by key, giving a stable query string
+		// regardless of the order params appeared in originally.
+		u.RawQuery = u.Query().Encode()
+	}
+
+	return u.String()
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestNormalizedCacheKey(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty path treated as root", "http://example.com", "http://example.com/"},
+		{"root path unchanged", "http://example.com/", "http://example.com/"},
+		{"host is lowercased", "http://EXAMPLE.com/blog", "http://example.com/blog"},
+		{"dot segments are cleaned", "http://example.com/a/./b/../c", "http://example.com/a/c"},
+		{"trailing slash is preserved", "http://example.com/blog/", "http://example.com/blog/"},
+		{"no trailing slash stays bare", "http://example.com/blog", "http://example.com/blog"},
+		{"query params are sorted", "http://example.com/p?b=2&a=1", "http://example.com/p?a=1&b=2"},
+		{"fragment is stripped", "http://example.com/p#section", "http://example.com/p"},
+		{"malformed url is returned as-is", "http://[::1", "http://[::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizedCacheKey(tt.in); got != tt.want {
+				t.Errorf("normalizedCacheKey(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizedCacheKey_CollapsesEquivalentURLs(t *testing.T) {
+	a := normalizedCacheKey("http://EXAMPLE.com/blog/?b=2&a=1#x")
+	b := normalizedCacheKey("http://example.com/blog/?a=1&b=2")
+	if a != b {
+		t.Errorf("expected equivalent URLs to normalize to the same key, got %q and %q", a, b)
+	}
+}